@@ -0,0 +1,193 @@
+// Copyright © 2023 The Tekton Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// severity controls which validator.Result bucket a script linter's
+// findings are reported under.
+type severity string
+
+const (
+	severityError   severity = "error"
+	severityWarning severity = "warning"
+	severityInfo    severity = "info"
+)
+
+func (s severity) orDefault() severity {
+	switch s {
+	case severityError, severityWarning, severityInfo:
+		return s
+	default:
+		return severityError
+	}
+}
+
+// fileConfig is the shape of a user-supplied `.catlin.yaml`/`.catlin.toml`.
+// It is intentionally close to the in-memory config/linter types so the
+// loader can merge it with the built-ins with little translation.
+type fileConfig struct {
+	// Linters maps a shebang regexp (anchored the same way as the
+	// built-ins, e.g. `(/usr/bin/env |.*/bin/)bash`) to the chain of
+	// linters that should run against scripts matching it.
+	Linters map[string]linterChainConfig `yaml:"linters" toml:"linters"`
+
+	// Disable lists shebang regexps - either built-in or declared in
+	// Linters - that should not be run at all.
+	Disable []string `yaml:"disable" toml:"disable"`
+
+	// ImagePolicy configures the image policy linter. See ImagePolicy.
+	ImagePolicy imagePolicyConfig `yaml:"imagePolicy" toml:"imagePolicy"`
+}
+
+type linterChainConfig struct {
+	// Severity overrides how findings from this chain are reported:
+	// "error", "warning", or "info". Defaults to "error".
+	Severity string `yaml:"severity" toml:"severity"`
+
+	Linters []linterInvocationConfig `yaml:"linters" toml:"linters"`
+}
+
+type linterInvocationConfig struct {
+	Cmd string `yaml:"cmd" toml:"cmd"`
+
+	// Args are passed to Cmd as-is, except that an argument containing
+	// the literal `{{.File}}` placeholder has it replaced with the path
+	// of the temporary file holding the script. This lets linters that
+	// don't take the filename as their last argument (hadolint, `shfmt
+	// -d`, ruff, mypy, a PowerShell analyzer, ...) be wired up. When no
+	// argument contains the placeholder, the filename is appended at the
+	// end, matching catlin's built-in linters.
+	Args []string `yaml:"args" toml:"args"`
+
+	// Env is merged into the child process's environment, in addition to
+	// the parent's.
+	Env map[string]string `yaml:"env" toml:"env"`
+}
+
+// imagePolicyConfig is the `imagePolicy` section of a catlin config file.
+// See ImagePolicy for the in-memory equivalent consumed by the linter.
+type imagePolicyConfig struct {
+	AllowedRegistries []string              `yaml:"allowedRegistries" toml:"allowedRegistries"`
+	TrustedSigners    []trustedSignerConfig `yaml:"trustedSigners" toml:"trustedSigners"`
+	RequireSBOM       bool                  `yaml:"requireSBOM" toml:"requireSBOM"`
+	MaxImageAge       string                `yaml:"maxImageAge" toml:"maxImageAge"`
+}
+
+type trustedSignerConfig struct {
+	Key      string `yaml:"key" toml:"key"`
+	Identity string `yaml:"identity" toml:"identity"`
+	Issuer   string `yaml:"issuer" toml:"issuer"`
+}
+
+// LoadConfig reads a catlin script linter config file and merges it with
+// the built-in defaults: a shebang regexp declared in path overrides the
+// built-in chain for that regexp (if any); regexps not mentioned in path
+// keep their built-in behavior; and any regexp named in the file's
+// `disable` list is dropped entirely, built-in or not.
+func LoadConfig(path string) ([]config, error) {
+	fc, err := parseFileConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return mergeConfig(NewConfig(), *fc), nil
+}
+
+// parseFileConfig reads and unmarshals a `.catlin.yaml`/`.catlin.toml` file
+// without applying any merging, so both the script linter and the image
+// policy linter can load their section of the same file.
+func parseFileConfig(path string) (*fileConfig, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading linter config %s: %w", path, err)
+	}
+
+	var fc fileConfig
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(raw, &fc); err != nil {
+			return nil, fmt.Errorf("parsing linter config %s: %w", path, err)
+		}
+	case ".toml":
+		if err := toml.Unmarshal(raw, &fc); err != nil {
+			return nil, fmt.Errorf("parsing linter config %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unrecognized linter config extension %q, want .yaml, .yml or .toml", ext)
+	}
+
+	return &fc, nil
+}
+
+func mergeConfig(defaults []config, fc fileConfig) []config {
+	disabled := make(map[string]bool, len(fc.Disable))
+	for _, d := range fc.Disable {
+		disabled[d] = true
+	}
+
+	merged := make([]config, 0, len(defaults)+len(fc.Linters))
+	seen := make(map[string]bool, len(fc.Linters))
+
+	for _, c := range defaults {
+		if disabled[c.regexp] {
+			continue
+		}
+		if override, ok := fc.Linters[c.regexp]; ok {
+			merged = append(merged, toConfig(c.regexp, override))
+			seen[c.regexp] = true
+			continue
+		}
+		merged = append(merged, c)
+	}
+
+	extra := make([]string, 0, len(fc.Linters))
+	for re := range fc.Linters {
+		if seen[re] || disabled[re] {
+			continue
+		}
+		extra = append(extra, re)
+	}
+	sort.Strings(extra)
+
+	for _, re := range extra {
+		merged = append(merged, toConfig(re, fc.Linters[re]))
+	}
+
+	return merged
+}
+
+func toConfig(regexp string, chain linterChainConfig) config {
+	c := config{
+		regexp:   regexp,
+		severity: severity(chain.Severity).orDefault(),
+	}
+	for _, l := range chain.Linters {
+		c.linters = append(c.linters, linter{
+			cmd:  l.Cmd,
+			args: l.Args,
+			env:  l.Env,
+		})
+	}
+	return c
+}
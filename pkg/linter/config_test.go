@@ -0,0 +1,105 @@
+// Copyright © 2023 The Tekton Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeConfig(t *testing.T) {
+	shRegexp := `(/usr/bin/env |.*/bin/)sh`
+
+	t.Run("regexp not mentioned keeps its built-in behavior", func(t *testing.T) {
+		merged := mergeConfig(NewConfig(), fileConfig{})
+		if len(merged) != len(NewConfig()) {
+			t.Fatalf("got %d configs, want %d (the unmodified built-ins)", len(merged), len(NewConfig()))
+		}
+	})
+
+	t.Run("regexp declared in file overrides the built-in chain", func(t *testing.T) {
+		fc := fileConfig{
+			Linters: map[string]linterChainConfig{
+				shRegexp: {Severity: "warning", Linters: []linterInvocationConfig{{Cmd: "custom-sh-linter"}}},
+			},
+		}
+		merged := mergeConfig(NewConfig(), fc)
+		if len(merged) != len(NewConfig()) {
+			t.Fatalf("got %d configs, want %d (override replaces, not appends)", len(merged), len(NewConfig()))
+		}
+		for _, c := range merged {
+			if c.regexp != shRegexp {
+				continue
+			}
+			if c.severity != severityWarning {
+				t.Errorf("overridden chain severity = %q, want %q", c.severity, severityWarning)
+			}
+			if len(c.linters) != 1 || c.linters[0].cmd != "custom-sh-linter" {
+				t.Errorf("overridden chain linters = %+v, want a single custom-sh-linter", c.linters)
+			}
+			return
+		}
+		t.Fatalf("merged configs do not contain %q", shRegexp)
+	})
+
+	t.Run("regexp named in disable is dropped entirely", func(t *testing.T) {
+		merged := mergeConfig(NewConfig(), fileConfig{Disable: []string{shRegexp}})
+		for _, c := range merged {
+			if c.regexp == shRegexp {
+				t.Fatalf("disabled regexp %q is still present in merged configs", shRegexp)
+			}
+		}
+		if len(merged) != len(NewConfig())-1 {
+			t.Fatalf("got %d configs, want %d (one fewer than the built-ins)", len(merged), len(NewConfig())-1)
+		}
+	})
+
+	t.Run("new regexps declared in the file are appended in sorted order", func(t *testing.T) {
+		fc := fileConfig{
+			Linters: map[string]linterChainConfig{
+				"zzz-last":   {Linters: []linterInvocationConfig{{Cmd: "z"}}},
+				"aaa-first":  {Linters: []linterInvocationConfig{{Cmd: "a"}}},
+				"mmm-middle": {Linters: []linterInvocationConfig{{Cmd: "m"}}},
+			},
+		}
+		merged := mergeConfig(nil, fc)
+		var got []string
+		for _, c := range merged {
+			got = append(got, c.regexp)
+		}
+		want := []string{"aaa-first", "mmm-middle", "zzz-last"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("merged regexp order = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestTemplateArgs(t *testing.T) {
+	t.Run("placeholder is substituted in place", func(t *testing.T) {
+		got := templateArgs([]string{"-f", filePlaceholder, "--strict"}, "/tmp/script.sh")
+		want := []string{"-f", "/tmp/script.sh", "--strict"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("templateArgs = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("no placeholder appends the file at the end", func(t *testing.T) {
+		got := templateArgs([]string{"-s", "bash"}, "/tmp/script.sh")
+		want := []string{"-s", "bash", "/tmp/script.sh"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("templateArgs = %v, want %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,319 @@
+// Copyright © 2023 The Tekton Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/bundle"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/tektoncd/catlin/pkg/parser"
+	"github.com/tektoncd/catlin/pkg/validator"
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+// TrustedSigner identifies a signer that an image policy accepts: either a
+// static public key reference, or a keyless Fulcio identity/issuer pair.
+type TrustedSigner struct {
+	// Key is a reference to a cosign public key (e.g. a `cosign.pub` file
+	// path or a KMS URI). Mutually exclusive with Identity/Issuer.
+	Key string
+	// Identity and Issuer are a keyless Fulcio certificate identity, e.g.
+	// Identity "https://github.com/org/repo/.github/workflows/release.yml@refs/heads/main"
+	// and Issuer "https://token.actions.githubusercontent.com".
+	Identity string
+	Issuer   string
+}
+
+// ImagePolicy is the set of checks the image policy linter enforces
+// against every step image in a Task.
+type ImagePolicy struct {
+	// AllowedRegistries lists the registry hosts (and optionally
+	// repository prefixes, e.g. "gcr.io/tekton-releases") images may come
+	// from. An empty list allows any registry.
+	AllowedRegistries []string
+	// TrustedSigners, when non-empty, requires every image to carry a
+	// valid cosign signature from at least one of these signers.
+	TrustedSigners []TrustedSigner
+	// RequireSBOM requires every image to have an SBOM attestation
+	// attached. The attestation is checked with cosign's default options;
+	// TrustedSigners are not consulted for it.
+	RequireSBOM bool
+	// MaxImageAge, when non-zero, flags images whose config creation time
+	// (when the image itself was built) predates time.Now().Add(-MaxImageAge).
+	// The ages of the individual base layers are not inspected.
+	MaxImageAge time.Duration
+}
+
+// LoadImagePolicy reads the `imagePolicy` section of a catlin config file.
+func LoadImagePolicy(path string) (ImagePolicy, error) {
+	fc, err := parseFileConfig(path)
+	if err != nil {
+		return ImagePolicy{}, err
+	}
+
+	var maxAge time.Duration
+	if fc.ImagePolicy.MaxImageAge != "" {
+		maxAge, err = time.ParseDuration(fc.ImagePolicy.MaxImageAge)
+		if err != nil {
+			return ImagePolicy{}, fmt.Errorf("parsing imagePolicy.maxImageAge %q: %w", fc.ImagePolicy.MaxImageAge, err)
+		}
+	}
+
+	signers := make([]TrustedSigner, 0, len(fc.ImagePolicy.TrustedSigners))
+	for _, s := range fc.ImagePolicy.TrustedSigners {
+		signers = append(signers, TrustedSigner{Key: s.Key, Identity: s.Identity, Issuer: s.Issuer})
+	}
+
+	return ImagePolicy{
+		AllowedRegistries: fc.ImagePolicy.AllowedRegistries,
+		TrustedSigners:    signers,
+		RequireSBOM:       fc.ImagePolicy.RequireSBOM,
+		MaxImageAge:       maxAge,
+	}, nil
+}
+
+// Rule IDs reported by imagePolicyLinter.validateImage. These are stable
+// identifiers (e.g. for SARIF's ruleId, see pkg/validator/sarif) - do not
+// renumber or repurpose one once released.
+const (
+	RuleImageNotAllowed = "CATLIN-IMAGE-NOT-ALLOWED"
+	RuleImageUnsigned   = "CATLIN-IMAGE-UNSIGNED"
+	RuleImageNoSBOM     = "CATLIN-IMAGE-NO-SBOM"
+	RuleImageTooOld     = "CATLIN-IMAGE-TOO-OLD"
+)
+
+// imagePolicyLinter validates step images against an ImagePolicy: allowed
+// registries, required cosign signatures, required SBOM attestations, and
+// a maximum base image age.
+type imagePolicyLinter struct {
+	res    *parser.Resource
+	policy ImagePolicy
+	jobs   int
+}
+
+var _ validator.Validator = (*imagePolicyLinter)(nil)
+
+// NewImagePolicyLinter constructs a linter that checks every step image in
+// the resource against policy.
+func NewImagePolicyLinter(r *parser.Resource, policy ImagePolicy) *imagePolicyLinter {
+	return &imagePolicyLinter{res: r, policy: policy}
+}
+
+// WithJobs sets the number of step images l validates concurrently,
+// overriding validator.DefaultJobs. It returns l for chaining off a
+// constructor.
+func (l *imagePolicyLinter) WithJobs(jobs int) *imagePolicyLinter {
+	l.jobs = jobs
+	return l
+}
+
+// location is the best-effort source location findings are reported
+// against: the file the resource was parsed from. See taskValidator.location
+// (pkg/validator) for why this is file-only rather than file+line.
+func (l *imagePolicyLinter) location() *validator.Location {
+	if l.res == nil || l.res.Path == "" {
+		return nil
+	}
+	return &validator.Location{File: l.res.Path}
+}
+
+// Validate implements validator.Validator by calling ValidateContext with a
+// background context and the linter's configured job count.
+// nolint: staticcheck
+func (l *imagePolicyLinter) Validate() validator.Result {
+	return l.ValidateContext(context.Background())
+}
+
+// ValidateContext is like Validate, but validates step images concurrently
+// on a bounded worker pool and stops launching new checks once ctx is done.
+// nolint: staticcheck
+func (l *imagePolicyLinter) ValidateContext(ctx context.Context) validator.Result {
+	result := validator.Result{}
+
+	res, err := l.res.ToType()
+	if err != nil {
+		result.Report("", validator.SeverityError, l.location(), "Failed to decode to a Task - %s", err)
+		return result
+	}
+
+	var images []string
+	switch task := res.(type) {
+	case *v1.Task:
+		for _, step := range task.Spec.Steps {
+			images = append(images, step.Image)
+		}
+	case *v1beta1.Task:
+		for _, step := range task.Spec.Steps {
+			images = append(images, step.Image)
+		}
+	}
+
+	result.Append(validator.RunConcurrent(ctx, l.jobs, len(images), func(ctx context.Context, i int) validator.Result {
+		return l.validateImage(images[i])
+	}))
+
+	return result
+}
+
+func (l *imagePolicyLinter) validateImage(img string) validator.Result {
+	result := validator.Result{}
+
+	if !l.registryAllowed(img) {
+		result.Report(RuleImageNotAllowed, validator.SeverityError, l.location(), "Image %q is not from an allowed registry (%s)", img, strings.Join(l.policy.AllowedRegistries, ", "))
+		return result
+	}
+
+	ref, err := name.ParseReference(img)
+	if err != nil {
+		result.Report("", validator.SeverityWarning, l.location(), "Image %q: could not parse reference, skipping registry policy checks: %s", img, err)
+		return result
+	}
+
+	desc, err := remote.Get(ref, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		result.Report("", validator.SeverityWarning, l.location(), "Image %q: could not fetch manifest, skipping registry policy checks: %s", img, err)
+		return result
+	}
+
+	if len(l.policy.TrustedSigners) > 0 {
+		if err := l.verifySignature(ref); err != nil {
+			result.Report(RuleImageUnsigned, validator.SeverityError, l.location(), "Image %q is not signed by a trusted signer: %s", img, err)
+		} else {
+			result.Report(RuleImageUnsigned, validator.SeverityInfo, l.location(), "Image %q signature verified", img)
+		}
+	}
+
+	if l.policy.RequireSBOM {
+		if ok, err := l.hasSBOMAttestation(ref); err != nil {
+			result.Report(RuleImageNoSBOM, validator.SeverityWarning, l.location(), "Image %q: could not check for an SBOM attestation: %s", img, err)
+		} else if !ok {
+			result.Report(RuleImageNoSBOM, validator.SeverityError, l.location(), "Image %q has no SBOM attestation", img)
+		} else {
+			result.Report(RuleImageNoSBOM, validator.SeverityInfo, l.location(), "Image %q has an SBOM attestation", img)
+		}
+	}
+
+	if l.policy.MaxImageAge > 0 {
+		rimg, err := desc.Image()
+		if err != nil {
+			result.Report("", validator.SeverityWarning, l.location(), "Image %q: could not inspect image config, skipping age check: %s", img, err)
+			return result
+		}
+		cfg, err := rimg.ConfigFile()
+		if err != nil {
+			result.Report("", validator.SeverityWarning, l.location(), "Image %q: could not read image config, skipping age check: %s", img, err)
+			return result
+		}
+		if age := time.Since(cfg.Created.Time); age > l.policy.MaxImageAge {
+			result.Report(RuleImageTooOld, validator.SeverityError, l.location(), "Image %q was built %s ago, which exceeds the maximum allowed age of %s", img, age.Round(time.Hour), l.policy.MaxImageAge)
+		}
+	}
+
+	return result
+}
+
+// registryAllowed reports whether img's registry and repository match one
+// of the policy's AllowedRegistries entries on a path-segment boundary -
+// e.g. "gcr.io/tekton-releases" allows "gcr.io/tekton-releases/foo" but not
+// "gcr.io/tekton-releases-evil/foo", and "gcr.io" allows any repository on
+// that registry but not a different host like "gcr.io.attacker.com".
+func (l *imagePolicyLinter) registryAllowed(img string) bool {
+	if len(l.policy.AllowedRegistries) == 0 {
+		return true
+	}
+
+	ref, err := name.ParseReference(img)
+	if err != nil {
+		return false
+	}
+	repo := ref.Context()
+	full := repo.RegistryStr() + "/" + repo.RepositoryStr()
+
+	for _, allowed := range l.policy.AllowedRegistries {
+		allowed = strings.TrimSuffix(strings.TrimSuffix(allowed, "/*"), "/")
+		if full == allowed || strings.HasPrefix(full, allowed+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// verifySignature checks ref against every configured TrustedSigner,
+// succeeding as soon as one verifies.
+func (l *imagePolicyLinter) verifySignature(ref name.Reference) error {
+	var lastErr error
+	for _, signer := range l.policy.TrustedSigners {
+		co := &cosign.CheckOpts{}
+		if signer.Identity != "" {
+			co.Identities = []cosign.Identity{{Subject: signer.Identity, Issuer: signer.Issuer}}
+		}
+		if signer.Key != "" {
+			pub, err := loadPublicKey(signer.Key)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			co.SigVerifier = pub
+		}
+
+		if _, _, err := cosign.VerifyImageSignatures(context.Background(), ref, co); err != nil {
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no trusted signers configured")
+	}
+	return lastErr
+}
+
+// loadPublicKey resolves a cosign key reference - a local file path or a
+// KMS URI - to a verifier.
+func loadPublicKey(keyRef string) (signature.Verifier, error) {
+	return cosign.PublicKeyFromKeyRef(context.Background(), keyRef)
+}
+
+// hasSBOMAttestation reports whether ref has an attached in-toto
+// attestation whose payload mentions one of the common SBOM formats: it
+// looks for the substring "spdx" or "cyclonedx" anywhere in the payload,
+// not only in the predicate type.
+func (l *imagePolicyLinter) hasSBOMAttestation(ref name.Reference) (bool, error) {
+	co := &cosign.CheckOpts{IgnoreTlog: true}
+	atts, _, err := cosign.VerifyImageAttestations(context.Background(), ref, co)
+	if err != nil {
+		return false, err
+	}
+	for _, att := range atts {
+		payload, err := bundle.Payload(att)
+		if err != nil {
+			continue
+		}
+		if strings.Contains(string(payload), "spdx") || strings.Contains(string(payload), "cyclonedx") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,42 @@
+// Copyright © 2023 The Tekton Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linter
+
+import "testing"
+
+func TestRegistryAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		img     string
+		want    bool
+	}{
+		{name: "no policy allows everything", allowed: nil, img: "gcr.io/tekton-releases/foo:v1", want: true},
+		{name: "exact repo prefix match", allowed: []string{"gcr.io/tekton-releases"}, img: "gcr.io/tekton-releases/foo:v1", want: true},
+		{name: "lookalike repo prefix is rejected", allowed: []string{"gcr.io/tekton-releases"}, img: "gcr.io/tekton-releases-evil/backdoor:latest", want: false},
+		{name: "host-only allows any repo on that host", allowed: []string{"gcr.io"}, img: "gcr.io/some/deep/repo:v1", want: true},
+		{name: "lookalike host is rejected", allowed: []string{"gcr.io"}, img: "gcr.io.attacker.com/x:latest", want: false},
+		{name: "different registry is rejected", allowed: []string{"gcr.io/tekton-releases"}, img: "docker.io/library/ubuntu:1.0", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &imagePolicyLinter{policy: ImagePolicy{AllowedRegistries: tt.allowed}}
+			if got := l.registryAllowed(tt.img); got != tt.want {
+				t.Errorf("registryAllowed(%q) with allowed=%v = %v, want %v", tt.img, tt.allowed, got, tt.want)
+			}
+		})
+	}
+}
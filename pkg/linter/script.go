@@ -15,30 +15,86 @@
 package linter
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"regexp"
 	"strings"
 
 	"github.com/tektoncd/catlin/pkg/parser"
+	"github.com/tektoncd/catlin/pkg/resolver"
 	"github.com/tektoncd/catlin/pkg/validator"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 )
 
+// Rule IDs reported by taskLinter.validateScript that aren't specific to
+// one linter invocation (see linterRuleID for those). Stable identifiers
+// (e.g. for SARIF's ruleId, see pkg/validator/sarif) - do not renumber or
+// repurpose one once released.
+const (
+	RuleScriptShebangEnv    = "CATLIN-SCRIPT-SHEBANG-ENV"
+	RuleScriptInvalidConfig = "CATLIN-SCRIPT-INVALID-CONFIG"
+	RuleScriptLinterMissing = "CATLIN-SCRIPT-LINTER-MISSING"
+	RuleScriptTempFile      = "CATLIN-SCRIPT-TEMPFILE"
+	RuleScriptRefUnresolved = "CATLIN-SCRIPT-REF-UNRESOLVED"
+)
+
 type taskLinter struct {
 	res     *parser.Resource
 	configs []config
+	jobs    int
+
+	// offline, when true, skips resolving step.ref and reports a warning
+	// instead. See WithOffline.
+	offline bool
+	// resolvers resolves step.ref pointers to a StepAction. Defaults to
+	// resolver.NewDefaultRegistry() when nil.
+	resolvers resolver.Registry
+}
+
+// WithJobs sets the number of scripts t lints concurrently, overriding
+// validator.DefaultJobs. It returns t for chaining off a constructor.
+func (t *taskLinter) WithJobs(jobs int) *taskLinter {
+	t.jobs = jobs
+	return t
+}
+
+// WithOffline sets whether t should skip resolving step.ref pointers
+// (reporting a warning instead of following them). It returns t for
+// chaining off a constructor.
+func (t *taskLinter) WithOffline(offline bool) *taskLinter {
+	t.offline = offline
+	return t
+}
+
+// WithResolvers overrides the resolver.Registry used to follow step.ref
+// pointers. It returns t for chaining off a constructor.
+func (t *taskLinter) WithResolvers(reg resolver.Registry) *taskLinter {
+	t.resolvers = reg
+	return t
+}
+
+// location is the best-effort source location findings are reported
+// against: the file the resource was parsed from. See taskValidator.location
+// (pkg/validator) for why this is file-only rather than file+line.
+func (t *taskLinter) location() *validator.Location {
+	if t.res == nil || t.res.Path == "" {
+		return nil
+	}
+	return &validator.Location{File: t.res.Path}
 }
 
 type linter struct {
 	cmd  string
 	args []string
+	env  map[string]string
 }
 
 type config struct {
-	regexp  string
-	linters []linter
+	regexp   string
+	severity severity
+	linters  []linter
 }
 
 // NewConfig construct default config
@@ -46,7 +102,8 @@ func NewConfig() []config {
 	return []config{
 		// Default one is the first one
 		{
-			regexp: `(/usr/bin/env |.*/bin/)sh`,
+			regexp:   `(/usr/bin/env |.*/bin/)sh`,
+			severity: severityError,
 			linters: []linter{
 				{
 					cmd:  "shellcheck",
@@ -59,7 +116,8 @@ func NewConfig() []config {
 			},
 		},
 		{
-			regexp: `(/usr/bin/env |.*/bin/)bash`,
+			regexp:   `(/usr/bin/env |.*/bin/)bash`,
+			severity: severityError,
 			linters: []linter{
 				{
 					cmd:  "shellcheck",
@@ -72,7 +130,8 @@ func NewConfig() []config {
 			},
 		},
 		{
-			regexp: `(/usr/bin/env\s|.*/bin/|/usr/libexec/platform-)python(23)?`,
+			regexp:   `(/usr/bin/env\s|.*/bin/|/usr/libexec/platform-)python(23)?`,
+			severity: severityError,
 			linters: []linter{
 				{
 					cmd:  "pylint",
@@ -83,28 +142,40 @@ func NewConfig() []config {
 	}
 }
 
-// NewScriptLinter construct a new task lister struct
+// NewScriptLinter construct a new task lister struct using the built-in
+// linter chains.
 func NewScriptLinter(r *parser.Resource) *taskLinter {
 	return &taskLinter{res: r, configs: NewConfig()}
 }
 
+// NewScriptLinterFromConfig constructs a task linter whose chains are
+// loaded from configPath (a `.catlin.yaml` or `.catlin.toml` file) merged
+// over the built-in defaults. See LoadConfig for the merge rules.
+func NewScriptLinterFromConfig(r *parser.Resource, configPath string) (*taskLinter, error) {
+	configs, err := LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return &taskLinter{res: r, configs: configs}, nil
+}
+
 // nolint: staticcheck
-func (t *taskLinter) validateScript(taskName string, script string, configs []config, stepName string) validator.Result {
+func (t *taskLinter) validateScript(ctx context.Context, taskName string, script string, configs []config, stepName string) validator.Result {
 	result := validator.Result{}
 
 	// use /bin/sh by default if no shbang
-	if script[0:2] != "#!" {
+	if !strings.HasPrefix(script, "#!") {
 		script = "#!/usr/bin/env sh\n" + script
 	} else { // using a shbang, check if we have /usr/bin/env
-		if script[0:14] != "#!/usr/bin/env" {
-			result.Warn("step: %s is not using #!/usr/bin/env ", taskName)
+		if !strings.HasPrefix(script, "#!/usr/bin/env") {
+			result.Report(RuleScriptShebangEnv, validator.SeverityWarning, t.location(), "step: %s is not using #!/usr/bin/env ", taskName)
 		}
 	}
 
 	for _, config := range configs {
 		matched, err := regexp.MatchString(`^#!`+config.regexp+`\n`, script)
 		if err != nil {
-			result.Error("Invalid regexp: %s", config.regexp)
+			result.Report(RuleScriptInvalidConfig, validator.SeverityError, t.location(), "Invalid regexp: %s", config.regexp)
 			return result
 		}
 
@@ -115,32 +186,36 @@ func (t *taskLinter) validateScript(taskName string, script string, configs []co
 		for _, linter := range config.linters {
 			execpath, err := exec.LookPath(linter.cmd)
 			if err != nil {
-				result.Error("Couldn't find the linter %s in the path", linter.cmd)
+				result.Report(RuleScriptLinterMissing, validator.SeverityError, t.location(), "Couldn't find the linter %s in the path", linter.cmd)
 				return result
 			}
 			tmpfile, err := os.CreateTemp("", "catlin-script-linter")
 			if err != nil {
-				result.Error("Cannot create temporary files")
+				result.Report(RuleScriptTempFile, validator.SeverityError, t.location(), "Cannot create temporary files")
 				return result
 			}
 			defer os.Remove(tmpfile.Name()) // clean up
 			if _, err := tmpfile.Write([]byte(script)); err != nil {
-				result.Error("Cannot write to temporary files")
+				result.Report(RuleScriptTempFile, validator.SeverityError, t.location(), "Cannot write to temporary files")
 				return result
 			}
 			if err := tmpfile.Close(); err != nil {
-				result.Error("Cannot close temporary files")
+				result.Report(RuleScriptTempFile, validator.SeverityError, t.location(), "Cannot close temporary files")
 				return result
 			}
 
-			// TODO: perhaps the filename is not necessary will be at the end of
-			// a command, may need some variable interpolation so the linter can
-			// specify where the filaname is into the command line.
-			cmd := exec.Command(execpath, append(linter.args, tmpfile.Name())...)
+			args := templateArgs(linter.args, tmpfile.Name())
+			cmd := exec.CommandContext(ctx, execpath, args...)
+			if len(linter.env) > 0 {
+				cmd.Env = os.Environ()
+				for k, v := range linter.env {
+					cmd.Env = append(cmd.Env, k+"="+v)
+				}
+			}
 			out, err := cmd.CombinedOutput()
 			if err != nil {
 				outt := strings.ReplaceAll(string(out), tmpfile.Name(), taskName+"-"+stepName)
-				result.Error("%s, %s failed:\n%s", execpath, linter.args, outt)
+				report(&result, linterRuleID(linter.cmd, outt), config.severity, t.location(), "%s, %s failed:\n%s", execpath, args, outt)
 			}
 		}
 	}
@@ -148,45 +223,188 @@ func (t *taskLinter) validateScript(taskName string, script string, configs []co
 	return result
 }
 
+// filePlaceholder is substituted with the linted script's temp file path in
+// a linter's args. It lets linters that don't take the filename as their
+// last argument (hadolint, `shfmt -d`, ruff, mypy, a PowerShell analyzer,
+// ...) be configured through a catlin config file.
+const filePlaceholder = "{{.File}}"
+
+// templateArgs renders args against file, substituting filePlaceholder
+// wherever it occurs. If no arg references the placeholder, file is
+// appended at the end, matching catlin's built-in linters.
+func templateArgs(args []string, file string) []string {
+	rendered := make([]string, 0, len(args)+1)
+	hasPlaceholder := false
+	for _, a := range args {
+		if strings.Contains(a, filePlaceholder) {
+			hasPlaceholder = true
+			a = strings.ReplaceAll(a, filePlaceholder, file)
+		}
+		rendered = append(rendered, a)
+	}
+	if !hasPlaceholder {
+		rendered = append(rendered, file)
+	}
+	return rendered
+}
+
+// report records msg against result, under the bucket matching sev, tagged
+// with ruleID and located at loc.
+func report(result *validator.Result, ruleID string, sev severity, loc *validator.Location, format string, args ...interface{}) {
+	var vsev validator.Severity
+	switch sev.orDefault() {
+	case severityWarning:
+		vsev = validator.SeverityWarning
+	case severityInfo:
+		vsev = validator.SeverityInfo
+	default:
+		vsev = validator.SeverityError
+	}
+	result.Report(ruleID, vsev, loc, format, args...)
+}
+
+// shellcheckCodeRegexp pulls the first shellcheck rule code (e.g. SC2086)
+// out of a failed shellcheck invocation's output.
+var shellcheckCodeRegexp = regexp.MustCompile(`SC[0-9]{4}`)
+
+// linterRuleID derives a stable rule ID for a failed linter invocation.
+// Shellcheck findings get a per-rule ID (e.g. CATLIN-SCRIPT-SHELLCHECK-SC2086)
+// by scraping the rule code out of its output; other linters get one ID per
+// command (e.g. CATLIN-SCRIPT-PYLINT).
+func linterRuleID(cmd, output string) string {
+	if cmd == "shellcheck" {
+		if code := shellcheckCodeRegexp.FindString(output); code != "" {
+			return "CATLIN-SCRIPT-SHELLCHECK-" + code
+		}
+		return "CATLIN-SCRIPT-SHELLCHECK"
+	}
+	return "CATLIN-SCRIPT-" + strings.ToUpper(cmd)
+}
+
+// stepRef is a step's remote `ref` pointer, carried alongside a scriptStep
+// so it can be resolved concurrently with the rest of the linting.
+type stepRef struct {
+	resolver string
+	params   map[string]string
+}
+
+// scriptStep is a (Task name, Step name, Step script) tuple to validate,
+// collected up front so the actual linting can run concurrently. Either
+// script is set, or ref is - a step with a ref has its script resolved
+// just before linting.
+type scriptStep struct {
+	taskName string
+	stepName string
+	script   string
+	ref      *stepRef
+}
+
 // nolint: staticcheck
-func (t *taskLinter) collectOverSteps(steps interface{}, name string, result *validator.Result) {
+func collectOverSteps(steps interface{}, name string) []scriptStep {
+	var out []scriptStep
 	if s, ok := steps.([]v1beta1.Step); ok {
 		for _, step := range s {
 			if step.Script != "" {
-				result.Append(t.validateScript(name, step.Script, t.configs, step.Name))
+				out = append(out, scriptStep{taskName: name, stepName: step.Name, script: step.Script})
 			}
 		}
 	} else if s, ok := steps.([]v1.Step); ok {
 		for _, step := range s {
 			if step.Script != "" {
-				result.Append(t.validateScript(name, step.Script, t.configs, step.Name))
+				out = append(out, scriptStep{taskName: name, stepName: step.Name, script: step.Script})
+				continue
+			}
+			if step.Ref != nil {
+				params := make(map[string]string, len(step.Ref.Params))
+				for _, p := range step.Ref.Params {
+					params[p.Name] = p.Value.StringVal
+				}
+				out = append(out, scriptStep{
+					taskName: name,
+					stepName: step.Name,
+					ref:      &stepRef{resolver: string(step.Ref.Resolver), params: params},
+				})
 			}
 		}
 	}
+	return out
 }
 
+// Validate implements validator.Validator by calling ValidateContext with
+// a background context and the linter's configured job count.
 // nolint: staticcheck
 func (t *taskLinter) Validate() validator.Result {
+	return t.ValidateContext(context.Background())
+}
+
+// ValidateContext is like Validate, but lints the scripts of distinct
+// steps concurrently on a bounded worker pool and stops launching new
+// linter invocations - killing any already running - once ctx is done.
+// nolint: staticcheck
+func (t *taskLinter) ValidateContext(ctx context.Context) validator.Result {
 	result := validator.Result{}
 	res, err := t.res.ToType()
 	if err != nil {
-		result.Error("Failed to decode to a Task - %s", err)
+		result.Report("", validator.SeverityError, t.location(), "Failed to decode to a Task - %s", err)
 		return result
 	}
 
+	var steps []scriptStep
 	switch strings.ToLower(t.res.Kind) {
 	case "task":
 		if res.(*v1.Task) != nil {
 			task := res.(*v1.Task)
-			t.collectOverSteps(task.Spec.Steps, task.ObjectMeta.Name, &result)
+			steps = collectOverSteps(task.Spec.Steps, task.ObjectMeta.Name)
 		} else {
 			task := res.(*v1beta1.Task)
-			t.collectOverSteps(task.Spec.Steps, task.ObjectMeta.Name, &result)
+			steps = collectOverSteps(task.Spec.Steps, task.ObjectMeta.Name)
 		}
 
 	case "clustertask":
 		task := res.(*v1beta1.ClusterTask)
-		t.collectOverSteps(task.Spec.Steps, task.ObjectMeta.Name, &result)
+		steps = collectOverSteps(task.Spec.Steps, task.ObjectMeta.Name)
+
+	case "stepaction":
+		sa := res.(*v1beta1.StepAction)
+		if sa.Spec.Script != "" {
+			steps = append(steps, scriptStep{taskName: sa.ObjectMeta.Name, stepName: sa.ObjectMeta.Name, script: sa.Spec.Script})
+		}
+	}
+
+	result.Append(validator.RunConcurrent(ctx, t.jobs, len(steps), func(ctx context.Context, i int) validator.Result {
+		return t.validateScriptStep(ctx, steps[i])
+	}))
+	return result
+}
+
+// validateScriptStep resolves s.ref into a script when set, then lints the
+// script the same way for an inline or a resolved step.
+func (t *taskLinter) validateScriptStep(ctx context.Context, s scriptStep) validator.Result {
+	if s.ref == nil {
+		return t.validateScript(ctx, s.taskName, s.script, t.configs, s.stepName)
+	}
+
+	result := validator.Result{}
+
+	if t.offline {
+		result.Report(RuleScriptRefUnresolved, validator.SeverityWarning, t.location(), "Step %q uses a remote ref (resolver %q); skipping resolution because --offline was set", s.stepName, s.ref.resolver)
+		return result
+	}
+
+	reg := t.resolvers
+	if reg == nil {
+		reg = resolver.NewDefaultRegistry()
 	}
+
+	sa, err := reg.Resolve(ctx, s.ref.resolver, s.ref.params)
+	if err != nil {
+		result.Report(RuleScriptRefUnresolved, validator.SeverityError, t.location(), "Step %q: could not resolve remote ref (resolver %q): %s", s.stepName, s.ref.resolver, err)
+		return result
+	}
+	if sa.Script == "" {
+		return result
+	}
+
+	result.Append(t.validateScript(ctx, s.taskName, sa.Script, t.configs, s.stepName))
 	return result
 }
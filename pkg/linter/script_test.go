@@ -0,0 +1,40 @@
+// Copyright © 2023 The Tekton Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linter
+
+import (
+	"context"
+	"testing"
+)
+
+// TestValidateScriptShortScript guards against a regression where a script
+// shorter than the shebang prefixes being checked (e.g. one resolved from
+// an untrusted remote ref) caused validateScript to panic on a slice
+// bounds out of range error.
+func TestValidateScriptShortScript(t *testing.T) {
+	lt := &taskLinter{configs: NewConfig()}
+
+	for _, script := range []string{"a", "#", "#!", ""} {
+		script := script
+		t.Run(script, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("validateScript(%q) panicked: %v", script, r)
+				}
+			}()
+			lt.validateScript(context.Background(), "task", script, lt.configs, "step")
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright © 2023 The Tekton Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"gopkg.in/yaml.v3"
+)
+
+// stepActionManifest is the minimal shape of a StepAction YAML manifest
+// catlin cares about.
+type stepActionManifest struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name string `yaml:"name"`
+	} `yaml:"metadata"`
+	Spec struct {
+		Image  string `yaml:"image"`
+		Script string `yaml:"script"`
+	} `yaml:"spec"`
+}
+
+// HTTPResolver resolves a step.ref whose `params` include a `url` pointing
+// directly at a StepAction manifest, mirroring Tekton's built-in `http`
+// resolver.
+type HTTPResolver struct {
+	Client *http.Client
+}
+
+func (h *HTTPResolver) Resolve(ctx context.Context, params map[string]string) (*StepAction, error) {
+	url := params["url"]
+	if url == "" {
+		return nil, fmt.Errorf(`http resolver requires a "url" param`)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: unexpected status %s", url, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", url, err)
+	}
+
+	return parseStepAction(body)
+}
+
+func parseStepAction(raw []byte) (*StepAction, error) {
+	var m stepActionManifest
+	if err := yaml.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("parsing StepAction manifest: %w", err)
+	}
+	return &StepAction{Name: m.Metadata.Name, Image: m.Spec.Image, Script: m.Spec.Script}, nil
+}
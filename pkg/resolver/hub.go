@@ -0,0 +1,94 @@
+// Copyright © 2023 The Tekton Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// defaultHubAPIBaseURL is the public Tekton Hub API.
+const defaultHubAPIBaseURL = "https://api.hub.tekton.dev/v1"
+
+// HubResolver resolves a step.ref whose `params` identify a resource in
+// the Tekton Hub (or an Artifact Hub compatible instance), mirroring
+// Tekton's built-in `hub` resolver. Expected params: "catalog" (default
+// "tekton-catalog-stepactions"), "kind" (default "stepaction"), "name",
+// and "version" (default "latest").
+type HubResolver struct {
+	Client  *http.Client
+	BaseURL string
+}
+
+type hubResourceResponse struct {
+	Data struct {
+		YAML string `json:"yaml"`
+	} `json:"data"`
+}
+
+func (h *HubResolver) Resolve(ctx context.Context, params map[string]string) (*StepAction, error) {
+	name := params["name"]
+	if name == "" {
+		return nil, fmt.Errorf(`hub resolver requires a "name" param`)
+	}
+	catalog := params["catalog"]
+	if catalog == "" {
+		catalog = "tekton-catalog-stepactions"
+	}
+	kind := params["kind"]
+	if kind == "" {
+		kind = "stepaction"
+	}
+	version := params["version"]
+	if version == "" {
+		version = "latest"
+	}
+
+	base := h.BaseURL
+	if base == "" {
+		base = defaultHubAPIBaseURL
+	}
+	url := fmt.Sprintf("%s/resource/%s/%s/%s/%s", base, catalog, kind, name, version)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s from Tekton Hub: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s from Tekton Hub: unexpected status %s", name, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading Tekton Hub response for %s: %w", name, err)
+	}
+
+	var hr hubResourceResponse
+	if err := json.Unmarshal(body, &hr); err != nil {
+		return nil, fmt.Errorf("parsing Tekton Hub response for %s: %w", name, err)
+	}
+
+	return parseStepAction([]byte(hr.Data.YAML))
+}
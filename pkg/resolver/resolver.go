@@ -0,0 +1,61 @@
+// Copyright © 2023 The Tekton Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resolver fetches the Tekton StepAction a `step.ref` points at,
+// so catlin's linters and validators can check it the same way they check
+// an inline step.
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// StepAction is the subset of a resolved tekton.dev/v1beta1 StepAction
+// that catlin's linters and validators need.
+type StepAction struct {
+	Name   string
+	Image  string
+	Script string
+}
+
+// Resolver resolves a step's `ref.params` into a StepAction.
+type Resolver interface {
+	Resolve(ctx context.Context, params map[string]string) (*StepAction, error)
+}
+
+// Registry dispatches to a Resolver by its `ref.resolver` name (e.g.
+// "hub", "http", "git").
+type Registry map[string]Resolver
+
+// Resolve looks up name in reg and resolves params against it.
+func (reg Registry) Resolve(ctx context.Context, name string, params map[string]string) (*StepAction, error) {
+	r, ok := reg[name]
+	if !ok {
+		return nil, fmt.Errorf("no resolver registered for %q", name)
+	}
+	return r.Resolve(ctx, params)
+}
+
+// NewDefaultRegistry returns the resolvers catlin supports out of the box:
+// "http", fetching a raw StepAction manifest by URL, and "hub", fetching
+// one from the Tekton Hub API. A "git" resolver is not included yet.
+func NewDefaultRegistry() Registry {
+	client := http.DefaultClient
+	return Registry{
+		"http": &HTTPResolver{Client: client},
+		"hub":  &HubResolver{Client: client, BaseURL: defaultHubAPIBaseURL},
+	}
+}
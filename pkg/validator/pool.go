@@ -0,0 +1,81 @@
+// Copyright © 2023 The Tekton Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// DefaultJobs is the worker pool size RunConcurrent falls back to when
+// called with jobs <= 0. It is a var, not a const, so a `--jobs` CLI flag
+// can override it process-wide.
+var DefaultJobs = runtime.GOMAXPROCS(0)
+
+// RunConcurrent runs fn(ctx, i) for every i in [0, n) on a bounded pool of
+// jobs workers (falling back to DefaultJobs when jobs <= 0), and merges
+// every call's Result into one, in index order.
+//
+// Each worker gets its own Result to write into - Result is not safe for
+// concurrent use - and results are merged via Append once every worker has
+// finished, so callers of RunConcurrent never need to synchronize.
+//
+// If ctx is canceled, RunConcurrent stops dispatching new work but still
+// waits for work already in flight, so fn implementations that honor ctx
+// (e.g. by using exec.CommandContext) can exit promptly and temp files get
+// cleaned up deterministically.
+func RunConcurrent(ctx context.Context, jobs, n int, fn func(ctx context.Context, i int) Result) Result {
+	result := Result{}
+	if n <= 0 {
+		return result
+	}
+	if jobs <= 0 {
+		jobs = DefaultJobs
+	}
+	if jobs > n {
+		jobs = n
+	}
+
+	results := make([]Result, n)
+	work := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				results[i] = fn(ctx, i)
+			}
+		}()
+	}
+
+dispatch:
+	for i := 0; i < n; i++ {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case work <- i:
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	for _, r := range results {
+		result.Append(r)
+	}
+	return result
+}
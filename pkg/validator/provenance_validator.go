@@ -0,0 +1,233 @@
+// Copyright © 2023 The Tekton Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+
+	"github.com/tektoncd/catlin/pkg/parser"
+)
+
+// artifactResultSuffixes are the Tekton Chains naming convention for results
+// that describe an artifact produced by a Step, used to build an in-toto
+// subject in the resulting SLSA provenance.
+var artifactResultSuffixes = []string{"_IMAGE_URL", "_IMAGE_DIGEST"}
+
+// typedArtifactResultNames are the names Chains recognizes for the newer
+// typed (object) artifact results.
+var typedArtifactResultNames = []string{"ARTIFACT_OUTPUTS", "ARTIFACT_INPUTS"}
+
+// imageBuildingCommands are binaries that build or push container images. A
+// Step that shells out to one of these without declaring artifact results
+// will not produce a usable SLSA subject.
+var imageBuildingCommands = []string{"docker", "buildah", "kaniko", "img", "podman"}
+
+// Rule IDs reported by provenanceValidator.validateStep. These are stable
+// identifiers (e.g. for SARIF's ruleId, see pkg/validator/sarif) - do not
+// renumber or repurpose one once released.
+const (
+	RuleProvenanceNoDigest        = "CATLIN-PROVENANCE-NO-DIGEST"
+	RuleProvenanceImageLatest     = "CATLIN-PROVENANCE-IMAGE-LATEST"
+	RuleProvenanceImageUnqual     = "CATLIN-PROVENANCE-IMAGE-UNQUALIFIED"
+	RuleProvenanceMissingArtifact = "CATLIN-PROVENANCE-MISSING-ARTIFACT-RESULT"
+)
+
+type provenanceValidator struct {
+	res *parser.Resource
+
+	// strict, when true, raises violations that are normally Warn to Error.
+	// This is intended for catalog authors who want a hard gate before
+	// publishing a Task that Tekton Chains will attest.
+	strict bool
+}
+
+var _ Validator = (*provenanceValidator)(nil)
+
+// NewProvenanceValidator constructs a validator that checks whether a Task
+// is structured so that Tekton Chains can produce well-formed SLSA v1
+// provenance for it: every step image pinned by digest, and every step that
+// produces an artifact declaring the results Chains expects.
+//
+// When strict is true, findings that are normally warnings (e.g. a step
+// image that isn't digest-pinned) are reported as errors instead.
+func NewProvenanceValidator(r *parser.Resource, strict bool) *provenanceValidator {
+	return &provenanceValidator{res: r, strict: strict}
+}
+
+// location is the best-effort source location findings are reported
+// against: the file the resource was parsed from. See taskValidator.location
+// for why this is file-only rather than file+line.
+func (t *provenanceValidator) location() *Location {
+	if t.res == nil || t.res.Path == "" {
+		return nil
+	}
+	return &Location{File: t.res.Path}
+}
+
+// nolint: staticcheck
+func (t *provenanceValidator) Validate() Result {
+	result := Result{}
+
+	res, err := t.res.ToType()
+	if err != nil {
+		result.Report("", SeverityError, t.location(), "Failed to decode to a Task - %s", err)
+		return result
+	}
+
+	switch task := res.(type) {
+	case *v1.Task:
+		for _, step := range task.Spec.Steps {
+			result.Append(t.validateStep(step))
+		}
+	case *v1beta1.Task:
+		for _, step := range task.Spec.Steps {
+			result.Append(t.validateStep(step))
+		}
+	}
+
+	return result
+}
+
+func (t *provenanceValidator) validateStep(step interface{}) Result {
+	result := Result{}
+
+	var (
+		stepName, img, script string
+		command, args         []string
+	)
+
+	switch s := step.(type) {
+	case v1.Step:
+		stepName, img, script, command, args = s.Name, s.Image, s.Script, s.Command, s.Args
+	case v1beta1.Step:
+		stepName, img, script, command, args = s.Name, s.Image, s.Script, s.Command, s.Args
+	default:
+		return result
+	}
+
+	if !isPinnedByDigest(img) {
+		t.report(&result, RuleProvenanceNoDigest, "Step %q uses image %q that isn't pinned by digest; Chains cannot produce a reproducible provenance subject for it - e.g. abc.io/img:v1@sha256:abcde", stepName, img)
+	}
+
+	if strings.EqualFold(img, "latest") || strings.HasSuffix(strings.ToLower(img), ":latest") {
+		t.report(&result, RuleProvenanceImageLatest, "Step %q uses image %q which must be tagged with a specific version", stepName, img)
+	} else if !strings.Contains(img, "/") || !isValidRegistry(img) {
+		t.report(&result, RuleProvenanceImageUnqual, "Step %q uses image %q; consider using a fully qualified registry so provenance subjects are unambiguous", stepName, img)
+	}
+
+	if shellsOutToImageBuilder(script, command, args) && !t.hasArtifactResults() {
+		t.report(&result, RuleProvenanceMissingArtifact, "Step %q builds or pushes an image but the Task declares no %s/%s results (or typed %s/%s results); Chains will not be able to attest the artifact it produces", stepName, artifactResultSuffixes[0], artifactResultSuffixes[1], typedArtifactResultNames[0], typedArtifactResultNames[1])
+	}
+
+	return result
+}
+
+// report records msg as an Error when running in strict mode, and as a
+// Warn otherwise, tagged with ruleID.
+func (t *provenanceValidator) report(result *Result, ruleID, format string, args ...interface{}) {
+	sev := SeverityWarning
+	if t.strict {
+		sev = SeverityError
+	}
+	result.Report(ruleID, sev, t.location(), format, args...)
+}
+
+// hasArtifactResults reports whether the Task declares at least one result
+// following the Chains artifact naming convention, either the legacy
+// `*_IMAGE_URL`/`*_IMAGE_DIGEST` pair or one of the typed
+// `ARTIFACT_OUTPUTS`/`ARTIFACT_INPUTS` results.
+func (t *provenanceValidator) hasArtifactResults() bool {
+	res, err := t.res.ToType()
+	if err != nil {
+		return false
+	}
+
+	var names []string
+	switch task := res.(type) {
+	case *v1.Task:
+		for _, r := range task.Spec.Results {
+			names = append(names, r.Name)
+		}
+	case *v1beta1.Task:
+		for _, r := range task.Spec.Results {
+			names = append(names, r.Name)
+		}
+	default:
+		return false
+	}
+
+	for _, name := range names {
+		for _, suffix := range artifactResultSuffixes {
+			if strings.HasSuffix(name, suffix) {
+				return true
+			}
+		}
+		for _, typed := range typedArtifactResultNames {
+			if name == typed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// shellsOutToImageBuilder reports whether a step's script or command/args
+// invoke a known image-building tool.
+func shellsOutToImageBuilder(script string, command, args []string) bool {
+	for _, tool := range imageBuildingCommands {
+		if containsWord(script, tool) {
+			return true
+		}
+	}
+
+	all := append(append([]string{}, command...), args...)
+	for _, a := range all {
+		for _, tool := range imageBuildingCommands {
+			if a == tool || strings.HasSuffix(a, "/"+tool) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isPinnedByDigest reports whether img is pinned by digest (`@sha256:...`),
+// regardless of whether it also carries a tag. This is deliberately
+// distinct from tagWithDigest in task_validator.go, which checks for a tag
+// *and* a digest together - here we only care that Chains has something
+// immutable to build a provenance subject from.
+func isPinnedByDigest(img string) bool {
+	if !strings.Contains(img, "@sha256") {
+		return false
+	}
+	_, err := name.NewDigest(img, name.WeakValidation)
+	return err == nil
+}
+
+func containsWord(s, word string) bool {
+	for _, line := range strings.Split(s, "\n") {
+		fields := strings.Fields(line)
+		for _, f := range fields {
+			if f == word || strings.HasSuffix(f, "/"+word) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+// Copyright © 2023 The Tekton Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import "testing"
+
+func TestIsPinnedByDigest(t *testing.T) {
+	tests := []struct {
+		name string
+		img  string
+		want bool
+	}{
+		{name: "digest only", img: "docker.io/library/ubuntu@sha256:e4e5b4e50a3ccf4f6e9adea7a99bb83f8e6f09c6f1c0d6e0b1e1c5e8b1b1b1b1", want: true},
+		{name: "tag and digest", img: "docker.io/library/ubuntu:1.0@sha256:e4e5b4e50a3ccf4f6e9adea7a99bb83f8e6f09c6f1c0d6e0b1e1c5e8b1b1b1b1", want: true},
+		{name: "tag only, no digest", img: "docker.io/library/ubuntu:1.0", want: false},
+		{name: "latest tag, no digest", img: "docker.io/library/ubuntu:latest", want: false},
+		{name: "no tag, no digest", img: "docker.io/library/ubuntu", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPinnedByDigest(tt.img); got != tt.want {
+				t.Errorf("isPinnedByDigest(%q) = %v, want %v", tt.img, got, tt.want)
+			}
+		})
+	}
+}
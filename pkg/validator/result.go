@@ -0,0 +1,135 @@
+// Copyright © 2020 The Tekton Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import "fmt"
+
+// Validator validates a parsed catalog resource and reports any findings.
+type Validator interface {
+	Validate() Result
+}
+
+// Severity is how serious a Message is.
+type Severity int
+
+const (
+	// SeverityError is a finding that should fail validation.
+	SeverityError Severity = iota
+	// SeverityWarning is a finding that should be surfaced but not fail
+	// validation.
+	SeverityWarning
+	// SeverityInfo is an informational finding, not a cause for concern
+	// on its own.
+	SeverityInfo
+)
+
+// String renders a Severity the way SARIF's `level` property spells it.
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "note"
+	default:
+		return "none"
+	}
+}
+
+// Location is the best-effort source position a Message is about: the
+// catalog YAML file, and the line/column of the offending step or script
+// within it, when known. Line and Column are 1-indexed; a zero value means
+// "unknown".
+type Location struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// Message is a single validation finding, with enough structure to render
+// as a SARIF result (see pkg/validator/sarif).
+type Message struct {
+	RuleID   string
+	Severity Severity
+	Location *Location
+	Text     string
+}
+
+// Result collects the findings produced while validating a resource. The
+// zero value is ready to use.
+//
+// Result itself is not safe for concurrent use: callers that validate
+// steps in parallel (see RunConcurrent) must give each goroutine its own
+// Result and merge them with Append from a single goroutine instead of
+// sharing one Result across workers.
+type Result struct {
+	Messages []Message
+
+	// Errors, Warnings and Infos mirror Messages as plain text, split by
+	// severity, for callers that just want to print findings. Report and
+	// the Error/Warn/Info helpers keep both in sync.
+	Errors   []string
+	Warnings []string
+	Infos    []string
+}
+
+// Report records a finding with a stable rule ID (e.g.
+// "CATLIN-STEP-IMAGE-LATEST") and, when known, the source location it
+// applies to. ruleID and loc may be empty/nil; they are what lets a SARIF
+// serializer produce a precise result instead of a free-text one.
+func (r *Result) Report(ruleID string, sev Severity, loc *Location, format string, args ...interface{}) {
+	text := fmt.Sprintf(format, args...)
+	r.Messages = append(r.Messages, Message{RuleID: ruleID, Severity: sev, Location: loc, Text: text})
+
+	switch sev {
+	case SeverityWarning:
+		r.Warnings = append(r.Warnings, text)
+	case SeverityInfo:
+		r.Infos = append(r.Infos, text)
+	default:
+		r.Errors = append(r.Errors, text)
+	}
+}
+
+// Error records a finding that should fail validation.
+func (r *Result) Error(format string, args ...interface{}) {
+	r.Report("", SeverityError, nil, format, args...)
+}
+
+// Warn records a finding that should be surfaced but not fail validation.
+func (r *Result) Warn(format string, args ...interface{}) {
+	r.Report("", SeverityWarning, nil, format, args...)
+}
+
+// Info records an informational finding, e.g. a policy check that passed
+// or a detail a catalog author may want to double check but that is not a
+// cause for concern on its own.
+func (r *Result) Info(format string, args ...interface{}) {
+	r.Report("", SeverityInfo, nil, format, args...)
+}
+
+// Append merges the findings of other into r.
+func (r *Result) Append(other Result) {
+	r.Messages = append(r.Messages, other.Messages...)
+	r.Errors = append(r.Errors, other.Errors...)
+	r.Warnings = append(r.Warnings, other.Warnings...)
+	r.Infos = append(r.Infos, other.Infos...)
+}
+
+// Failed reports whether any Error-level finding was recorded.
+func (r *Result) Failed() bool {
+	return len(r.Errors) > 0
+}
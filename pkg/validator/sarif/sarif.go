@@ -0,0 +1,166 @@
+// Copyright © 2023 The Tekton Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sarif serializes a validator.Result into a SARIF 2.1.0 log, so
+// `catlin validate` output can be ingested by GitHub Code Scanning,
+// GitLab, and other tools that speak the format natively.
+package sarif
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/tektoncd/catlin/pkg/validator"
+)
+
+const (
+	schemaURI = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+	version   = "2.1.0"
+
+	toolName           = "catlin"
+	toolInformationURI = "https://github.com/tektoncd/catlin"
+
+	// unknownRuleID is used for the (increasingly rare) findings that
+	// don't carry a rule ID yet. GitHub Code Scanning requires every
+	// result to reference a rule, so these still need to round-trip.
+	unknownRuleID = "CATLIN-UNSPECIFIED"
+)
+
+// log is the root SARIF object.
+type log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []run  `json:"runs"`
+}
+
+type run struct {
+	Tool    tool     `json:"tool"`
+	Results []result `json:"results"`
+}
+
+type tool struct {
+	Driver driver `json:"driver"`
+}
+
+type driver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri"`
+	Rules          []rule `json:"rules"`
+}
+
+type rule struct {
+	ID string `json:"id"`
+}
+
+type result struct {
+	RuleID    string     `json:"ruleId"`
+	Level     string     `json:"level"`
+	Message   message    `json:"message"`
+	Locations []location `json:"locations,omitempty"`
+}
+
+type message struct {
+	Text string `json:"text"`
+}
+
+type location struct {
+	PhysicalLocation physicalLocation `json:"physicalLocation"`
+}
+
+type physicalLocation struct {
+	ArtifactLocation artifactLocation `json:"artifactLocation"`
+	Region           *region          `json:"region,omitempty"`
+}
+
+type artifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type region struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// Marshal serializes res as a SARIF 2.1.0 log.
+func Marshal(res validator.Result) ([]byte, error) {
+	return json.MarshalIndent(toLog(res), "", "  ")
+}
+
+func toLog(res validator.Result) log {
+	rules := map[string]bool{}
+	var results []result
+	for _, m := range res.Messages {
+		ruleID := m.RuleID
+		if ruleID == "" {
+			ruleID = unknownRuleID
+		}
+		rules[ruleID] = true
+
+		r := result{
+			RuleID:  ruleID,
+			Level:   m.Severity.String(),
+			Message: message{Text: m.Text},
+		}
+		if loc := toLocation(m); loc != nil {
+			r.Locations = []location{*loc}
+		}
+		results = append(results, r)
+	}
+
+	return log{
+		Schema:  schemaURI,
+		Version: version,
+		Runs: []run{
+			{
+				Tool: tool{
+					Driver: driver{
+						Name:           toolName,
+						InformationURI: toolInformationURI,
+						Rules:          toRules(rules),
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+}
+
+func toRules(seen map[string]bool) []rule {
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	rules := make([]rule, 0, len(ids))
+	for _, id := range ids {
+		rules = append(rules, rule{ID: id})
+	}
+	return rules
+}
+
+// toLocation builds a SARIF physicalLocation from a Message's Location.
+// The region is only included once we have a line number; best-effort
+// column info is included alongside it when known.
+func toLocation(m validator.Message) *location {
+	if m.Location == nil || m.Location.File == "" {
+		return nil
+	}
+
+	loc := &physicalLocation{ArtifactLocation: artifactLocation{URI: m.Location.File}}
+	if m.Location.Line > 0 {
+		loc.Region = &region{StartLine: m.Location.Line, StartColumn: m.Location.Column}
+	}
+	return &location{PhysicalLocation: *loc}
+}
@@ -15,6 +15,7 @@
 package validator
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
@@ -25,6 +26,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/tektoncd/catlin/pkg/parser"
+	"github.com/tektoncd/catlin/pkg/resolver"
 )
 
 const (
@@ -32,8 +34,32 @@ const (
 	braceMatchingRegex    = "(\\$(\\(%s.(?P<var>%s)\\)))"
 )
 
+// Rule IDs reported by taskValidator.validateStep. These are stable
+// identifiers (e.g. for SARIF's ruleId, see pkg/validator/sarif) - do not
+// renumber or repurpose one once released.
+const (
+	RuleStepImageUnqualified = "CATLIN-STEP-IMAGE-UNQUALIFIED"
+	RuleStepImageDigest      = "CATLIN-STEP-IMAGE-DIGEST-INVALID"
+	RuleStepImageNoDigest    = "CATLIN-STEP-IMAGE-NO-DIGEST"
+	RuleStepImageTag         = "CATLIN-STEP-IMAGE-TAG-INVALID"
+	RuleStepImageLatest      = "CATLIN-STEP-IMAGE-LATEST"
+	RuleStepSecretEnv        = "CATLIN-STEP-SECRET-ENV"
+	RuleStepSecretEnvFrom    = "CATLIN-STEP-SECRET-ENVFROM"
+	RuleStepScriptParam      = "CATLIN-STEP-SCRIPT-PARAM-DIRECT"
+	RuleStepImageHasVars     = "CATLIN-STEP-IMAGE-HAS-VARS"
+	RuleStepRefUnresolved    = "CATLIN-STEP-REF-UNRESOLVED"
+)
+
 type taskValidator struct {
-	res *parser.Resource
+	res  *parser.Resource
+	jobs int
+
+	// offline, when true, skips resolving step.ref and reports a warning
+	// instead. See WithOffline.
+	offline bool
+	// resolvers resolves step.ref pointers to a StepAction. Defaults to
+	// resolver.NewDefaultRegistry() when nil.
+	resolvers resolver.Registry
 }
 
 var _ Validator = (*taskValidator)(nil)
@@ -42,27 +68,87 @@ func NewTaskValidator(r *parser.Resource) *taskValidator {
 	return &taskValidator{res: r}
 }
 
+// location is the best-effort source location findings are reported
+// against: the file the resource was parsed from. Precise line/column
+// tracking requires threading that through from pkg/parser and isn't done
+// yet, but the file alone is enough for a SARIF result to be clickable.
+func (t *taskValidator) location() *Location {
+	if t.res == nil || t.res.Path == "" {
+		return nil
+	}
+	return &Location{File: t.res.Path}
+}
+
+// WithJobs sets the number of steps t validates concurrently, overriding
+// DefaultJobs. It returns t for chaining off a constructor.
+func (t *taskValidator) WithJobs(jobs int) *taskValidator {
+	t.jobs = jobs
+	return t
+}
+
+// WithOffline sets whether t should skip resolving step.ref pointers
+// (reporting a warning instead of following them). It returns t for
+// chaining off a constructor.
+func (t *taskValidator) WithOffline(offline bool) *taskValidator {
+	t.offline = offline
+	return t
+}
+
+// WithResolvers overrides the resolver.Registry used to follow step.ref
+// pointers. It returns t for chaining off a constructor.
+func (t *taskValidator) WithResolvers(reg resolver.Registry) *taskValidator {
+	t.resolvers = reg
+	return t
+}
+
+// Validate implements Validator by calling ValidateContext with a
+// background context and the validator's configured job count.
 // nolint: staticcheck
 func (t *taskValidator) Validate() Result {
+	return t.ValidateContext(context.Background())
+}
+
+// ValidateContext is like Validate, but validates steps concurrently on a
+// bounded worker pool and stops launching new step validations once ctx is
+// done.
+// nolint: staticcheck
+func (t *taskValidator) ValidateContext(ctx context.Context) Result {
 	result := Result{}
 
 	res, err := t.res.ToType()
 	if err != nil {
-		result.Error("Failed to decode to a Task - %s", err)
+		result.Report("", SeverityError, t.location(), "Failed to decode to a Task - %s", err)
 		return result
 	}
 
+	var steps []interface{}
 	switch task := res.(type) {
 	case *v1.Task:
 		for _, step := range task.Spec.Steps {
-			result.Append(t.validateStep(step))
+			steps = append(steps, step)
 		}
 	case *v1beta1.Task:
 		for _, step := range task.Spec.Steps {
-			result.Append(t.validateStep(step))
+			steps = append(steps, step)
 		}
+	case *v1beta1.StepAction:
+		// A standalone StepAction is validated as if it were the sole
+		// step of a Task.
+		steps = append(steps, v1beta1.Step{
+			Name:    task.ObjectMeta.Name,
+			Image:   task.Spec.Image,
+			Script:  task.Spec.Script,
+			Env:     task.Spec.Env,
+			EnvFrom: task.Spec.EnvFrom,
+		})
 	}
 
+	result.Append(RunConcurrent(ctx, t.jobs, len(steps), func(ctx context.Context, i int) Result {
+		r := t.validateStep(steps[i])
+		r.Append(t.validateStepRef(ctx, steps[i]))
+		return r
+	}))
+
 	return result
 }
 
@@ -95,23 +181,23 @@ func (t *taskValidator) validateStep(step interface{}) Result {
 	}
 
 	if _, usesVars := extractExpressionFromString(img, ""); usesVars {
-		result.Warn("Step %q uses image %q that contains variables; skipping validation", stepName, img)
+		result.Report(RuleStepImageHasVars, SeverityWarning, t.location(), "Step %q uses image %q that contains variables; skipping validation", stepName, img)
 		return result
 	}
 
 	if !strings.Contains(img, "/") || !isValidRegistry(img) {
-		result.Warn("Step %q uses image %q; consider using a fully qualified name - e.g. docker.io/library/ubuntu:1.0", stepName, img)
+		result.Report(RuleStepImageUnqualified, SeverityWarning, t.location(), "Step %q uses image %q; consider using a fully qualified name - e.g. docker.io/library/ubuntu:1.0", stepName, img)
 	}
 
 	if strings.Contains(img, "@sha256") {
 		rep, err := name.NewDigest(img, name.WeakValidation)
 		if err != nil {
-			result.Error("Step %q uses image %q with an invalid digest. Error: %s", stepName, img, err)
+			result.Report(RuleStepImageDigest, SeverityError, t.location(), "Step %q uses image %q with an invalid digest. Error: %s", stepName, img, err)
 			return result
 		}
 
 		if !tagWithDigest(rep.String()) {
-			result.Warn("Step %q uses image %q; consider using an image tagged with specific version along with digest eg. abc.io/img:v1@sha256:abcde", stepName, img)
+			result.Report(RuleStepImageNoDigest, SeverityWarning, t.location(), "Step %q uses image %q; consider using an image tagged with specific version along with digest eg. abc.io/img:v1@sha256:abcde", stepName, img)
 		}
 
 		return result
@@ -119,12 +205,12 @@ func (t *taskValidator) validateStep(step interface{}) Result {
 
 	ref, err := name.NewTag(img, name.WeakValidation)
 	if err != nil {
-		result.Error("Step %q uses image %q with an invalid tag. Error: %s", stepName, img, err)
+		result.Report(RuleStepImageTag, SeverityError, t.location(), "Step %q uses image %q with an invalid tag. Error: %s", stepName, img, err)
 		return result
 	}
 
 	if strings.EqualFold(ref.Identifier(), "latest") {
-		result.Error("Step %q uses image %q which must be tagged with a specific version", stepName, img)
+		result.Report(RuleStepImageLatest, SeverityError, t.location(), "Step %q uses image %q which must be tagged with a specific version", stepName, img)
 	}
 
 	// According to [CIS benchmarks](https://cloud.google.com/kubernetes-engine/docs/concepts/cis-benchmarks).
@@ -133,12 +219,12 @@ func (t *taskValidator) validateStep(step interface{}) Result {
 		switch version {
 		case "v1":
 			if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil {
-				result.Warn("Step %q uses secret to populate env %q. Prefer using secrets as files over secrets as environment variables", stepName, e.Name)
+				result.Report(RuleStepSecretEnv, SeverityWarning, t.location(), "Step %q uses secret to populate env %q. Prefer using secrets as files over secrets as environment variables", stepName, e.Name)
 			}
 
 		case "v1beta1":
 			if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil {
-				result.Warn("Step %q uses secret to populate env %q. Prefer using secrets as files over secrets as environment variables", stepName, e.Name)
+				result.Report(RuleStepSecretEnv, SeverityWarning, t.location(), "Step %q uses secret to populate env %q. Prefer using secrets as files over secrets as environment variables", stepName, e.Name)
 			}
 		}
 	}
@@ -146,11 +232,11 @@ func (t *taskValidator) validateStep(step interface{}) Result {
 		switch version {
 		case "v1":
 			if e.SecretRef != nil {
-				result.Warn("Step %q uses secret as environment variables. Prefer using secrets as files over secrets as environment variables", stepName)
+				result.Report(RuleStepSecretEnvFrom, SeverityWarning, t.location(), "Step %q uses secret as environment variables. Prefer using secrets as files over secrets as environment variables", stepName)
 			}
 		case "v1beta1":
 			if e.SecretRef != nil {
-				result.Warn("Step %q uses secret as environment variables. Prefer using secrets as files over secrets as environment variables", stepName)
+				result.Report(RuleStepSecretEnvFrom, SeverityWarning, t.location(), "Step %q uses secret as environment variables. Prefer using secrets as files over secrets as environment variables", stepName)
 			}
 		}
 	}
@@ -158,7 +244,7 @@ func (t *taskValidator) validateStep(step interface{}) Result {
 	if script != "" {
 		expr, _ := extractExpressionFromString(script, "params")
 		if expr != "" {
-			result.Warn(
+			result.Report(RuleStepScriptParam, SeverityWarning, t.location(),
 				"Step %q references %q directly from its script block. For reliability and security, consider putting the param into an environment variable of the Step and accessing that environment variable in your script instead.",
 				stepName,
 				expr)
@@ -168,6 +254,43 @@ func (t *taskValidator) validateStep(step interface{}) Result {
 	return result
 }
 
+// validateStepRef follows a v1 Step's `ref` (a remote StepAction reference
+// resolved via resolver.Resolver) and validates the StepAction it points
+// at the same way an inline step is validated. Steps without a ref, and
+// older v1beta1.Step values (which don't support ref), are a no-op.
+func (t *taskValidator) validateStepRef(ctx context.Context, step interface{}) Result {
+	result := Result{}
+
+	s, ok := step.(v1.Step)
+	if !ok || s.Ref == nil {
+		return result
+	}
+
+	if t.offline {
+		result.Report(RuleStepRefUnresolved, SeverityWarning, t.location(), "Step %q uses a remote ref (resolver %q); skipping resolution because --offline was set", s.Name, s.Ref.Resolver)
+		return result
+	}
+
+	reg := t.resolvers
+	if reg == nil {
+		reg = resolver.NewDefaultRegistry()
+	}
+
+	params := make(map[string]string, len(s.Ref.Params))
+	for _, p := range s.Ref.Params {
+		params[p.Name] = p.Value.StringVal
+	}
+
+	sa, err := reg.Resolve(ctx, string(s.Ref.Resolver), params)
+	if err != nil {
+		result.Report(RuleStepRefUnresolved, SeverityError, t.location(), "Step %q: could not resolve remote ref (resolver %q): %s", s.Name, s.Ref.Resolver, err)
+		return result
+	}
+
+	result.Append(t.validateStep(v1.Step{Name: sa.Name, Image: sa.Image, Script: sa.Script}))
+	return result
+}
+
 // copied from tektoncd/pipeline
 func extractExpressionFromString(s, prefix string) (string, bool) {
 	pattern := fmt.Sprintf(braceMatchingRegex, prefix, parameterSubstitution)